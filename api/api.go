@@ -0,0 +1,90 @@
+// Package api builds and diffs the textual API manifest the "tracer
+// api" subcommand works with, the way cmd/api computes the exported API
+// of a standard library package: one deterministic line per method, so
+// a diff between two manifests is a diff between two signatures.
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+// Entry is one interface to include in the manifest, together with the
+// package it was parsed from so its methods can be import-qualified.
+type Entry struct {
+	Package   *types.Package
+	Interface types.Interface
+}
+
+// Manifest renders entries as sorted lines of the form
+// "pkg.Interface.Method(paramTypes) returnTypes", using the same
+// gen.FormatParam formatter the wrapper generators use, so the manifest
+// reads the same as the signatures in generated code.
+func Manifest(entries []Entry) []string {
+	var lines []string
+	for _, e := range entries {
+		importMap := gen.BuildImportMap(e.Package)
+		for _, m := range e.Interface.Methods {
+			params := strings.Join(gen.FormatParams(m.Params, importMap), ", ")
+			returns := strings.Join(gen.FormatParams(m.Returns, importMap), ", ")
+			if len(m.Returns) > 1 {
+				returns = "(" + returns + ")"
+			}
+			line := fmt.Sprintf("%s.%s.%s(%s) %s", e.Package.PkgPath, e.Interface.Name, m.Name, params, returns)
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// ReadManifest reads a manifest file previously produced by Manifest.
+func ReadManifest(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// Diff compares an old manifest against a new one. removed entries are
+// always a breaking change; added entries are breaking only when the
+// caller hasn't opted into -allow_new.
+func Diff(old, new []string) (removed, added []string) {
+	oldSet := toSet(old)
+	newSet := toSet(new)
+	for _, l := range old {
+		if !newSet[l] {
+			removed = append(removed, l)
+		}
+	}
+	for _, l := range new {
+		if !oldSet[l] {
+			added = append(added, l)
+		}
+	}
+	return removed, added
+}
+
+func toSet(lines []string) map[string]bool {
+	set := make(map[string]bool, len(lines))
+	for _, l := range lines {
+		set[l] = true
+	}
+	return set
+}
@@ -0,0 +1,42 @@
+// Package config loads tracer.yaml, the gqlgen-style config file that
+// lists every interface to generate wrappers for and which wrapper
+// plugins to stack on each one.
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the top-level tracer.yaml document.
+type Config struct {
+	Interfaces []InterfaceConfig `yaml:"interfaces"`
+}
+
+// InterfaceConfig describes one interface to generate a wrapper file
+// for: where it lives, what to wrap it with, and where to write the
+// result.
+type InterfaceConfig struct {
+	Package     string            `yaml:"package"`
+	Interface   string            `yaml:"interface"`
+	Struct      string            `yaml:"struct"`
+	Wrappers    []string          `yaml:"wrappers"`
+	Args        map[string]string `yaml:"args"`
+	Instantiate map[string]string `yaml:"instantiate"`
+	Output      string            `yaml:"output"`
+}
+
+// Load reads and parses the tracer.yaml at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &cfg, nil
+}
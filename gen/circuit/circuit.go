@@ -0,0 +1,79 @@
+// Package circuit generates a Hystrix/gobreaker-style circuit breaker
+// wrapper, with one breaker per method.
+package circuit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func init() {
+	gen.Register(plugin{})
+}
+
+// gobreakerImport is the import path for the gobreaker.CircuitBreaker
+// the generated struct's breakers map holds.
+const gobreakerImport = "github.com/sony/gobreaker"
+
+// plugin adapts Gen to gen.Plugin so circuit can be selected by name
+// from tracer.yaml.
+type plugin struct{}
+
+func (plugin) Name() string { return "circuit" }
+
+func (plugin) ShouldSkip(types.Interface) bool { return false }
+
+func (plugin) Imports() []string { return []string{gobreakerImport} }
+
+func (plugin) Gen(b gen.Builder, iface types.Interface, importMap map[string]string, arg string) string {
+	return Gen(b, iface, importMap, arg)
+}
+
+// Gen writes a circuit-breaker wrapper around iface to b: every method
+// whose only return is error runs behind a gobreaker.CircuitBreaker
+// keyed by "<breakerVar>.<Method>" in the struct's breakers map. Methods
+// with additional return values pass straight through, for the same
+// reason retry.Gen skips them. It returns the generated struct's name.
+func Gen(b gen.Builder, iface types.Interface, importMap map[string]string, breakerVar string) string {
+	name := iface.Name + "Breaker"
+	strct := types.Struct{
+		Name: name,
+		Attrs: []types.Attr{
+			{Name: "next", Type: types.NamedParam{Typ: iface.Name + gen.WrappedTypeArgs(iface)}},
+			{Name: "breakers", Type: types.MapParam{
+				Key:  types.BasicParam{Typ: "string"},
+				Elem: types.PointerParam{Typ: types.NamedParam{Pkg: gobreakerImport, Typ: "CircuitBreaker"}},
+			}},
+		},
+		TypeParams: iface.TypeParams,
+	}
+	b.WriteStruct(strct)
+	for _, m := range iface.Methods {
+		method := m
+		b.WriteMethod(&strct, method, func(b gen.Builder) {
+			if !gen.IsErrorOnly(method) {
+				writeCall(b, "t.next", method)
+				return
+			}
+			args := strings.Join(gen.ParamRefs(method.Params), ", ")
+			key := breakerVar + "." + method.Name
+			b.WriteLine("_, err := t.breakers[%q].Execute(func() (interface{}, error) {", key)
+			b.WriteLine("return nil, t.next.%s(%s)", method.Name, args)
+			b.WriteLine("})")
+			b.WriteLine("return err")
+		})
+	}
+	return name
+}
+
+func writeCall(b gen.Builder, receiver string, m types.Method) {
+	call := fmt.Sprintf("%s.%s(%s)", receiver, m.Name, strings.Join(gen.ParamRefs(m.Params), ", "))
+	if len(m.Returns) > 0 {
+		b.WriteLine("return %s", call)
+	} else {
+		b.WriteLine("%s", call)
+	}
+}
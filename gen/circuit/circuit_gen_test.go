@@ -0,0 +1,34 @@
+package circuit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func TestGenImportsGobreaker(t *testing.T) {
+	iface := types.Interface{
+		Name: "Client",
+		Methods: []types.Method{
+			{Name: "Do", Returns: []types.Param{types.NamedParam{Typ: "error"}}},
+		},
+	}
+	importMap := gen.BuildImportMap(&types.Package{PkgPath: "example.com/client"}, gobreakerImport)
+
+	b := &gen.Buffer{ImportMap: importMap}
+	Gen(b, iface, importMap, "example")
+
+	var out bytes.Buffer
+	if _, err := b.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v: %s", err, out.String())
+	}
+	src := out.String()
+
+	alias := gen.ImportAlias(importMap, gobreakerImport)
+	if !strings.Contains(src, alias+".CircuitBreaker") {
+		t.Errorf("generated source doesn't reference %s.CircuitBreaker:\n%s", alias, src)
+	}
+}
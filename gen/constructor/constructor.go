@@ -0,0 +1,30 @@
+// Package constructor generates the New<Interface> function that wires
+// the wrapped struct up through each wrapper plugin's generated struct,
+// in the order the wrappers were composed.
+package constructor
+
+import (
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+// Gen writes a New<Interface> constructor to b: it takes the concrete
+// struct, assigns it to the interface, then wraps it with each of
+// wrappers in turn, so the result is
+// wrappers[n-1]{next: ... wrappers[0]{next: strct} ...}. When iface is
+// generic, the constructor and every wrapper reference are parameterized
+// over iface's type parameters; when iface is Instantiate's monomorphized
+// result, the struct and interface references carry its concrete TypeArgs
+// and the wrappers, which the generators declare without type parameters,
+// are referenced by name alone.
+func Gen(b gen.Builder, importMap map[string]string, iface types.Interface, strct types.Struct, wrappers []string) {
+	typeArgs := gen.WrappedTypeArgs(iface)
+	b.WriteLine("\nfunc New%s%s(s %s%s) %s%s {", iface.Name, gen.TypeParams(iface.TypeParams), strct.Name, typeArgs, iface.Name, typeArgs)
+	b.WriteLine("var t %s%s = s", iface.Name, typeArgs)
+	wrapperArgs := gen.TypeParamNames(iface.TypeParams)
+	for _, w := range wrappers {
+		b.WriteLine("t = %s%s{next: t}", w, wrapperArgs)
+	}
+	b.WriteLine("return t")
+	b.WriteLine("}")
+}
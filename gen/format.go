@@ -0,0 +1,102 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+// FormatParam renders p as Go source, qualifying named types from other
+// packages using importMap. It is the formatter Buffer.resolveParam
+// delegates to, shared with package api so a diff between two API
+// manifests means the same thing as a diff between two generated
+// signatures.
+func FormatParam(p types.Param, importMap map[string]string) string {
+	switch tp := p.(type) {
+	case types.BasicParam:
+		return tp.Typ
+	case types.NamedParam:
+		if tp.Pkg != "" {
+			if alias, ok := importMap[tp.Pkg]; ok {
+				return fmt.Sprintf("%s.%s", alias, tp.Typ)
+			}
+			return tp.Typ
+		}
+		return tp.Typ
+	case types.ArrayParam:
+		return fmt.Sprintf("[%d]%s", tp.Length, FormatParam(tp.Typ, importMap))
+	case types.SliceParam:
+		return fmt.Sprintf("[]%s", FormatParam(tp.Typ, importMap))
+	case types.PointerParam:
+		return fmt.Sprintf("*%s", FormatParam(tp.Typ, importMap))
+	case types.MapParam:
+		return fmt.Sprintf("map[%s]%s", FormatParam(tp.Key, importMap), FormatParam(tp.Elem, importMap))
+	case types.InterfaceParam:
+		var buf strings.Builder
+		if len(tp.Methods) == 0 {
+			fmt.Fprint(&buf, "interface{}")
+		} else if len(tp.Methods) == 1 {
+			fmt.Fprint(&buf, "interface{ ")
+			m := tp.Methods[0]
+			generateMethodSig(&buf, "", m.Name, FormatParams(m.Params, importMap), FormatParams(m.Returns, importMap))
+			fmt.Fprint(&buf, " }")
+		} else {
+			fmt.Fprint(&buf, "interface {")
+			for _, m := range tp.Methods {
+				fmt.Fprint(&buf, "\n")
+				generateMethodSig(&buf, "", m.Name, FormatParams(m.Params, importMap), FormatParams(m.Returns, importMap))
+			}
+			fmt.Fprint(&buf, "\n},\n")
+		}
+		return buf.String()
+	case types.VariadicParam:
+		return "..." + FormatParam(tp.Typ, importMap)
+	case types.ChanParam:
+		switch tp.Dir {
+		case types.SendOnly:
+			return "chan<- " + FormatParam(tp.Typ, importMap)
+		case types.RecvOnly:
+			return "<-chan " + FormatParam(tp.Typ, importMap)
+		default:
+			return "chan " + FormatParam(tp.Typ, importMap)
+		}
+	case types.FuncParam:
+		var buf strings.Builder
+		fmt.Fprintf(&buf, "func(%s)", strings.Join(FormatParams(tp.Params, importMap), ", "))
+		returns := FormatParams(tp.Returns, importMap)
+		if len(returns) == 1 {
+			fmt.Fprintf(&buf, " %s", returns[0])
+		} else if len(returns) > 1 {
+			fmt.Fprintf(&buf, " (%s)", strings.Join(returns, ", "))
+		}
+		return buf.String()
+	default:
+		return "<unsupported>"
+	}
+}
+
+// ParamRefs returns the "p0", "p1", ... call-site references for
+// params, suffixing the last one with "..." when it is variadic so it
+// forwards correctly to the wrapped method.
+func ParamRefs(params []types.Param) []string {
+	refs := make([]string, len(params))
+	for i := range params {
+		refs[i] = fmt.Sprintf("p%d", i)
+	}
+	if n := len(params); n > 0 {
+		if _, ok := params[n-1].(types.VariadicParam); ok {
+			refs[n-1] += "..."
+		}
+	}
+	return refs
+}
+
+// FormatParams maps FormatParam over params.
+func FormatParams(params []types.Param, importMap map[string]string) []string {
+	out := make([]string, len(params))
+	for i, p := range params {
+		out[i] = FormatParam(p, importMap)
+	}
+	return out
+}
@@ -20,111 +20,106 @@ type Builder interface {
 	Write(string, ...interface{})
 }
 
-type builder struct {
-	buf       *bytes.Buffer
-	importMap map[string]string
+// Buffer accumulates generated source and formats it on WriteTo. It is
+// the concrete Builder every Plugin and main is handed: main constructs
+// it directly (writing the header and imports with GenerateImports)
+// rather than going through NewBuilder, so the same Buffer can be shared
+// across several wrapper generators composed by constructor.Gen.
+type Buffer struct {
+	buf       bytes.Buffer
+	ImportMap map[string]string
 }
 
+// NewBuilder returns a Buffer pre-populated with the package header and
+// imports for pkg, for callers that only generate a single wrapper.
 func NewBuilder(pkg *types.Package) Builder {
-	b := builder{
-		buf:       &bytes.Buffer{},
-		importMap: buildImportMap(pkg),
-	}
+	b := &Buffer{ImportMap: BuildImportMap(pkg)}
 	b.WriteLine("// Code generated by tracer v0.0.1. DO NOT EDIT.")
 	b.WriteLine("package %s", pkg.Name)
-	b.writeImports()
+	GenerateImports(b, b.ImportMap)
 	return b
 }
 
-func (b builder) WriteTo(w io.Writer) (int, error) {
+func (b *Buffer) WriteTo(w io.Writer) (int, error) {
 	formatted, err := format.Source(b.buf.Bytes())
 	if err != nil {
 		return 0, err
 	}
-	return w.Write([]byte(formatted))
+	return w.Write(formatted)
 }
 
-func (b builder) WriteStruct(strct types.Struct) {
-	b.WriteLine("\ntype %s struct {", strct.Name)
+func (b *Buffer) WriteStruct(strct types.Struct) {
+	b.WriteLine("\ntype %s%s struct {", strct.Name, TypeParams(strct.TypeParams))
 	for _, attr := range strct.Attrs {
 		b.WriteLine("%s %s", attr.Name, b.resolveParam(attr.Type))
 	}
 	b.WriteLine("}")
 }
 
-func (b builder) WriteMethod(strct *types.Struct, method types.Method, callback func(b Builder)) {
+func (b *Buffer) WriteMethod(strct *types.Struct, method types.Method, callback func(b Builder)) {
 	b.Write("\nfunc ")
 	if strct != nil {
-		b.Write("(t %s) ", strct.Name)
+		b.Write("(t %s%s) ", strct.Name, TypeParamNames(strct.TypeParams))
 	}
-	generateMethodSig(b.buf, "", method.Name, b.resolveParams(method.Params), b.resolveParams(method.Returns))
+	generateMethodSig(&b.buf, "", method.Name+TypeParams(method.TypeParams), b.resolveParams(method.Params), b.resolveParams(method.Returns))
 	b.WriteLine(" {")
 	callback(b)
 	b.WriteLine("}")
 }
 
-func (b builder) WriteLine(str string, a ...interface{}) {
+func (b *Buffer) WriteLine(str string, a ...interface{}) {
 	b.Write(str+"\n", a...)
 }
 
-func (b builder) Write(str string, a ...interface{}) {
-	fmt.Fprintf(b.buf, str, a...)
+func (b *Buffer) Write(str string, a ...interface{}) {
+	fmt.Fprintf(&b.buf, str, a...)
+}
+
+func (b *Buffer) resolveParams(params []types.Param) []string {
+	return FormatParams(params, b.ImportMap)
+}
+
+func (b *Buffer) resolveParam(p types.Param) string {
+	return FormatParam(p, b.ImportMap)
 }
 
-func (b builder) resolveParams(params []types.Param) []string {
-	resolved := make([]string, 0, len(params))
-	for _, p := range params {
-		resolved = append(resolved, b.resolveParam(p))
+// TypeParams renders a type parameter list with constraints, e.g.
+// "[T any, U constraints.Ordered]", or "" when tps is empty.
+func TypeParams(tps []types.TypeParam) string {
+	if len(tps) == 0 {
+		return ""
 	}
-	return resolved
+	parts := make([]string, len(tps))
+	for i, tp := range tps {
+		parts[i] = fmt.Sprintf("%s %s", tp.Name, tp.Constraint)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
 }
 
-func (b builder) resolveParam(p types.Param) string {
-	switch tp := p.(type) {
-	case types.BasicParam:
-		return tp.Typ
-	case types.NamedParam:
-		if tp.Pkg != "" {
-			if alias, ok := b.importMap[tp.Pkg]; ok {
-				return fmt.Sprintf("%s.%s", alias, tp.Typ)
-			} else {
-				return tp.Typ
-			}
-		}
-		return tp.Typ
-	case types.ArrayParam:
-		return fmt.Sprintf("[%d]%s", tp.Length, b.resolveParam(tp.Typ))
-	case types.SliceParam:
-		return fmt.Sprintf("[]%s", b.resolveParam(tp.Typ))
-	case types.PointerParam:
-		return fmt.Sprintf("*%s", b.resolveParam(tp.Typ))
-	case types.MapParam:
-		return fmt.Sprintf("map[%s]%s", b.resolveParam(tp.Key), b.resolveParam(tp.Elem))
-	case types.InterfaceParam:
-		var buf strings.Builder
-		if len(tp.Methods) == 0 {
-			fmt.Fprint(&buf, "interface{}")
-		} else if len(tp.Methods) == 1 {
-			fmt.Fprint(&buf, "interface{ ")
-			m := tp.Methods[0]
-			params := b.resolveParams(m.Params)
-			returns := b.resolveParams(m.Returns)
-			generateMethodSig(&buf, "", m.Name, params, returns)
-			fmt.Fprint(&buf, " }")
-		} else {
-			fmt.Fprint(&buf, "interface {")
-			for _, m := range tp.Methods {
-				fmt.Fprint(&buf, "\n")
-				params := b.resolveParams(m.Params)
-				returns := b.resolveParams(m.Returns)
-				generateMethodSig(&buf, "", m.Name, params, returns)
-			}
-			fmt.Fprint(&buf, "\n},\n")
-		}
-		return buf.String()
-	default:
-		return "<unsupported>"
+// WrappedTypeArgs renders the type arguments to use when referencing
+// iface itself, e.g. the "next" field's type in a wrapper struct: if
+// iface is Instantiate's monomorphized result, that's its concrete
+// TypeArgs (e.g. "[string]"); otherwise it's iface's own TypeParams,
+// forwarded as-is for the pass-through generic case.
+func WrappedTypeArgs(iface types.Interface) string {
+	if len(iface.TypeArgs) > 0 {
+		return "[" + strings.Join(iface.TypeArgs, ", ") + "]"
 	}
+	return TypeParamNames(iface.TypeParams)
+}
+
+// TypeParamNames renders just the names from a type parameter list, e.g.
+// "[T, U]", for use as type arguments in a receiver, a reference to the
+// generic type being wrapped, or a forwarding call.
+func TypeParamNames(tps []types.TypeParam) string {
+	if len(tps) == 0 {
+		return ""
+	}
+	names := make([]string, len(tps))
+	for i, tp := range tps {
+		names[i] = tp.Name
+	}
+	return "[" + strings.Join(names, ", ") + "]"
 }
 
 func generateMethodSig(b io.Writer, implementor, methodName string, params, returns []string) {
@@ -156,9 +151,13 @@ func generateMethodSig(b io.Writer, implementor, methodName string, params, retu
 	}
 }
 
-func (b builder) writeImports() {
+// GenerateImports writes an import declaration for each entry in
+// importMap to b, aliased as the map's value, and records importMap on
+// b so later WriteStruct/WriteMethod calls can qualify named types with
+// the same aliases.
+func GenerateImports(b *Buffer, importMap map[string]string) {
 	var imports []string
-	for imp, alias := range b.importMap {
+	for imp, alias := range importMap {
 		imports = append(imports, fmt.Sprintf("import %s \"%s\"", alias, imp))
 	}
 	sort.Strings(imports)
@@ -166,9 +165,15 @@ func (b builder) writeImports() {
 	if len(imports) > 0 {
 		b.WriteLine("")
 	}
+	b.ImportMap = importMap
 }
 
-func buildImportMap(pkg *types.Package) map[string]string {
+// BuildImportMap assigns each distinct package imported by pkg's
+// interfaces a short alias (i0, i1, ...) for use in generated code.
+// extra is folded in afterwards, for packages a wrapper plugin's own
+// struct fields or method bodies need that don't appear in pkg itself
+// (see Plugin.Imports).
+func BuildImportMap(pkg *types.Package, extra ...string) map[string]string {
 	importMap := map[string]string{}
 	for _, i := range pkg.Interfaces {
 		for _, p := range resolveMethodPackages(i.Methods) {
@@ -180,9 +185,49 @@ func buildImportMap(pkg *types.Package) map[string]string {
 			}
 		}
 	}
+	for _, p := range extra {
+		if _, ok := importMap[p]; !ok {
+			importMap[p] = fmt.Sprintf("i%d", len(importMap))
+		}
+	}
 	return importMap
 }
 
+// IsErrorOnly reports whether m's only return value is a bare error,
+// the condition retry.Gen and circuit.Gen use to decide whether a method
+// can be retried/circuit-broken: there's nowhere to stash additional
+// return values across a retry attempt or a breaker's func() (interface{}, error)
+// without a typed local the generator can't yet name.
+func IsErrorOnly(m types.Method) bool {
+	if len(m.Returns) != 1 {
+		return false
+	}
+	np, ok := m.Returns[0].(types.NamedParam)
+	return ok && np.Pkg == "" && np.Typ == "error"
+}
+
+// ContextParamIndex returns the index of m's first context.Context
+// parameter, or -1 if it has none.
+func ContextParamIndex(m types.Method) int {
+	for i, p := range m.Params {
+		if named, ok := p.(types.NamedParam); ok && named.Pkg == "context" && named.Typ == "Context" {
+			return i
+		}
+	}
+	return -1
+}
+
+// ImportAlias returns the alias importMap assigned pkg, for generated
+// code that needs to call a package-qualified function rather than just
+// reference a package-qualified type (which FormatParam already handles).
+// It returns pkg itself if importMap has no entry for it.
+func ImportAlias(importMap map[string]string, pkg string) string {
+	if alias, ok := importMap[pkg]; ok {
+		return alias
+	}
+	return pkg
+}
+
 func resolveMethodPackages(methods []types.Method) []string {
 	var pkgs []string
 	for _, m := range methods {
@@ -213,6 +258,12 @@ func resolvePackages(p types.Param) []string {
 		return append(resolvePackages(tp.Key), resolvePackages(tp.Elem)...)
 	case types.InterfaceParam:
 		return resolveMethodPackages(tp.Methods)
+	case types.VariadicParam:
+		return resolvePackages(tp.Typ)
+	case types.ChanParam:
+		return resolvePackages(tp.Typ)
+	case types.FuncParam:
+		return resolveMethodPackages([]types.Method{{Params: tp.Params, Returns: tp.Returns}})
 	default:
 		return []string{}
 	}
@@ -0,0 +1,46 @@
+package gen
+
+import (
+	"testing"
+
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func TestIsErrorOnly(t *testing.T) {
+	tests := []struct {
+		name string
+		m    types.Method
+		want bool
+	}{
+		{
+			name: "single error return",
+			m:    types.Method{Returns: []types.Param{types.NamedParam{Typ: "error"}}},
+			want: true,
+		},
+		{
+			name: "no returns",
+			m:    types.Method{},
+			want: false,
+		},
+		{
+			name: "error plus value",
+			m: types.Method{Returns: []types.Param{
+				types.NamedParam{Typ: "string"},
+				types.NamedParam{Typ: "error"},
+			}},
+			want: false,
+		},
+		{
+			name: "named type from another package called error",
+			m:    types.Method{Returns: []types.Param{types.NamedParam{Pkg: "myerrors", Typ: "error"}}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsErrorOnly(tt.m); got != tt.want {
+				t.Errorf("IsErrorOnly() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
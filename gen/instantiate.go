@@ -0,0 +1,122 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+// basicTypes are the predeclared type names parseTypeArg recognises
+// without a package qualifier.
+var basicTypes = map[string]bool{
+	"bool": true, "string": true, "error": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "byte": true, "rune": true,
+}
+
+// ParseTypeArgs parses a comma-separated type-argument list such as
+// "T=string,U=pkg.Type" into a map of type parameter name to type
+// argument, for use with Instantiate.
+func ParseTypeArgs(s string) (map[string]string, error) {
+	args := map[string]string{}
+	if s == "" {
+		return args, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid -instantiate entry %q, want T=Type", pair)
+		}
+		args[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return args, nil
+}
+
+// Instantiate monomorphizes iface by substituting every reference to one
+// of its type parameters with the corresponding entry in args, the way
+// go/ssa builds a monomorphic instantiation by walking a function body
+// and applying a type-substitution map to every type reference. The
+// returned interface has no type parameters of its own; its TypeArgs
+// records what each of iface's TypeParams was substituted with, so
+// wrapper generators can still reference the instantiated type (e.g.
+// "Client[string]") by name.
+func Instantiate(iface types.Interface, args map[string]string) types.Interface {
+	return types.Interface{
+		Name:     iface.Name,
+		Methods:  substituteMethods(iface.Methods, args),
+		TypeArgs: typeArgs(iface.TypeParams, args),
+	}
+}
+
+func typeArgs(tps []types.TypeParam, args map[string]string) []string {
+	if len(tps) == 0 {
+		return nil
+	}
+	out := make([]string, len(tps))
+	for i, tp := range tps {
+		out[i] = args[tp.Name]
+	}
+	return out
+}
+
+func substituteMethods(methods []types.Method, args map[string]string) []types.Method {
+	out := make([]types.Method, len(methods))
+	for i, m := range methods {
+		out[i] = types.Method{
+			Name:    m.Name,
+			Params:  substituteParams(m.Params, args),
+			Returns: substituteParams(m.Returns, args),
+		}
+	}
+	return out
+}
+
+func substituteParams(params []types.Param, args map[string]string) []types.Param {
+	out := make([]types.Param, len(params))
+	for i, p := range params {
+		out[i] = substituteParam(p, args)
+	}
+	return out
+}
+
+func substituteParam(p types.Param, args map[string]string) types.Param {
+	switch tp := p.(type) {
+	case types.NamedParam:
+		if tp.Pkg == "" {
+			if repl, ok := args[tp.Typ]; ok {
+				return parseTypeArg(repl)
+			}
+		}
+		return tp
+	case types.ArrayParam:
+		return types.ArrayParam{Length: tp.Length, Typ: substituteParam(tp.Typ, args)}
+	case types.SliceParam:
+		return types.SliceParam{Typ: substituteParam(tp.Typ, args)}
+	case types.PointerParam:
+		return types.PointerParam{Typ: substituteParam(tp.Typ, args)}
+	case types.MapParam:
+		return types.MapParam{Key: substituteParam(tp.Key, args), Elem: substituteParam(tp.Elem, args)}
+	case types.InterfaceParam:
+		return types.InterfaceParam{Methods: substituteMethods(tp.Methods, args)}
+	case types.VariadicParam:
+		return types.VariadicParam{Typ: substituteParam(tp.Typ, args)}
+	case types.ChanParam:
+		return types.ChanParam{Dir: tp.Dir, Typ: substituteParam(tp.Typ, args)}
+	case types.FuncParam:
+		return types.FuncParam{Params: substituteParams(tp.Params, args), Returns: substituteParams(tp.Returns, args)}
+	default:
+		return p
+	}
+}
+
+func parseTypeArg(s string) types.Param {
+	if basicTypes[s] {
+		return types.BasicParam{Typ: s}
+	}
+	if idx := strings.LastIndex(s, "."); idx >= 0 {
+		return types.NamedParam{Pkg: s[:idx], Typ: s[idx+1:]}
+	}
+	return types.NamedParam{Typ: s}
+}
@@ -0,0 +1,49 @@
+package gen_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/gen/timing"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+// TestInstantiateRendersConcreteTypeArgs exercises Instantiate() followed
+// by a wrapper plugin's Gen(), the way main.generate() composes them for
+// a tracer.yaml entry with instantiate set, and checks the generated struct references the
+// wrapped type with concrete type arguments rather than the bare generic
+// name.
+func TestInstantiateRendersConcreteTypeArgs(t *testing.T) {
+	iface := types.Interface{
+		Name: "Client",
+		Methods: []types.Method{
+			{Name: "Do", Returns: []types.Param{types.NamedParam{Typ: "error"}}},
+		},
+		TypeParams: []types.TypeParam{{Name: "T", Constraint: "any"}},
+	}
+
+	args, err := gen.ParseTypeArgs("T=string")
+	if err != nil {
+		t.Fatalf("ParseTypeArgs() error = %v", err)
+	}
+	monomorphized := gen.Instantiate(iface, args)
+
+	importMap := gen.BuildImportMap(&types.Package{PkgPath: "example.com/client"}, "time")
+	b := &gen.Buffer{ImportMap: importMap}
+	timing.Gen(b, monomorphized, importMap, "elapsed")
+
+	var out bytes.Buffer
+	if _, err := b.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v: %s", err, out.String())
+	}
+	src := out.String()
+
+	if !strings.Contains(src, "Client[string]") {
+		t.Errorf("generated source doesn't reference the monomorphized type Client[string]:\n%s", src)
+	}
+	if strings.Contains(src, "next\tClient\n") || strings.Contains(src, "next Client\n") {
+		t.Errorf("generated source references the bare generic type, want \"Client[string]\":\n%s", src)
+	}
+}
@@ -0,0 +1,105 @@
+// Package metrics generates a wrapper that records Prometheus request
+// counts, error counts and a duration histogram for every method of an
+// interface.
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func init() {
+	gen.Register(plugin{})
+}
+
+// prometheusImport is the import path for the prometheus CounterVec and
+// HistogramVec types the generated struct embeds.
+const prometheusImport = "github.com/prometheus/client_golang/prometheus"
+
+// plugin adapts Gen to gen.Plugin so metrics can be selected by name
+// from tracer.yaml.
+type plugin struct{}
+
+func (plugin) Name() string { return "metrics" }
+
+func (plugin) ShouldSkip(types.Interface) bool { return false }
+
+func (plugin) Imports() []string { return []string{"time", prometheusImport} }
+
+func (plugin) Gen(b gen.Builder, iface types.Interface, importMap map[string]string, arg string) string {
+	return Gen(b, iface, importMap, arg)
+}
+
+// Gen writes a metrics wrapper around iface to b: every method
+// increments a request counter, observes its duration in a histogram,
+// and, if its last return is error, increments an error counter keyed
+// by the error's string, each labelled with "<namespace>.<Method>". It
+// returns the generated struct's name.
+func Gen(b gen.Builder, iface types.Interface, importMap map[string]string, namespace string) string {
+	name := iface.Name + "Metrics"
+	strct := types.Struct{
+		Name: name,
+		Attrs: []types.Attr{
+			{Name: "next", Type: types.NamedParam{Typ: iface.Name + gen.WrappedTypeArgs(iface)}},
+			{Name: "requests", Type: types.PointerParam{Typ: types.NamedParam{Pkg: prometheusImport, Typ: "CounterVec"}}},
+			{Name: "errors", Type: types.PointerParam{Typ: types.NamedParam{Pkg: prometheusImport, Typ: "CounterVec"}}},
+			{Name: "durations", Type: types.PointerParam{Typ: types.NamedParam{Pkg: prometheusImport, Typ: "HistogramVec"}}},
+		},
+		TypeParams: iface.TypeParams,
+	}
+	b.WriteStruct(strct)
+	timePkg := gen.ImportAlias(importMap, "time")
+	for _, m := range iface.Methods {
+		method := m
+		errIdx := lastErrorIndex(method)
+		b.WriteMethod(&strct, method, func(b gen.Builder) {
+			b.WriteLine("start := %s.Now()", timePkg)
+			b.WriteLine("t.requests.WithLabelValues(%q).Inc()", namespace+"."+method.Name)
+			results := writeCallCaptured(b, "t.next", method)
+			b.WriteLine("t.durations.WithLabelValues(%q).Observe(%s.Since(start).Seconds())", namespace+"."+method.Name, timePkg)
+			if errIdx >= 0 {
+				b.WriteLine("if %s != nil {", results[errIdx])
+				b.WriteLine("t.errors.WithLabelValues(%q, %s.Error()).Inc()", namespace+"."+method.Name, results[errIdx])
+				b.WriteLine("}")
+			}
+			writeReturn(b, results)
+		})
+	}
+	return name
+}
+
+func lastErrorIndex(m types.Method) int {
+	if len(m.Returns) == 0 {
+		return -1
+	}
+	if np, ok := m.Returns[len(m.Returns)-1].(types.NamedParam); ok && np.Pkg == "" && np.Typ == "error" {
+		return len(m.Returns) - 1
+	}
+	return -1
+}
+
+// writeCallCaptured writes `r0, r1 := receiver.Method(p0, p1)` and
+// returns the result variable names, or just the bare call with no
+// return statement if the method has no returns.
+func writeCallCaptured(b gen.Builder, receiver string, m types.Method) []string {
+	call := fmt.Sprintf("%s.%s(%s)", receiver, m.Name, strings.Join(gen.ParamRefs(m.Params), ", "))
+	if len(m.Returns) == 0 {
+		b.WriteLine("%s", call)
+		return nil
+	}
+	results := make([]string, len(m.Returns))
+	for i := range results {
+		results[i] = fmt.Sprintf("r%d", i)
+	}
+	b.WriteLine("%s := %s", strings.Join(results, ", "), call)
+	return results
+}
+
+func writeReturn(b gen.Builder, results []string) {
+	if len(results) > 0 {
+		b.WriteLine("return %s", strings.Join(results, ", "))
+	}
+}
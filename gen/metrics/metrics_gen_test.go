@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func TestGenImportsTimeAndPrometheus(t *testing.T) {
+	iface := types.Interface{
+		Name: "Client",
+		Methods: []types.Method{
+			{Name: "Do", Returns: []types.Param{types.NamedParam{Typ: "error"}}},
+		},
+	}
+	importMap := gen.BuildImportMap(&types.Package{PkgPath: "example.com/client"}, "time", prometheusImport)
+
+	b := &gen.Buffer{ImportMap: importMap}
+	Gen(b, iface, importMap, "example")
+
+	var out bytes.Buffer
+	if _, err := b.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v: %s", err, out.String())
+	}
+	src := out.String()
+
+	timeAlias := gen.ImportAlias(importMap, "time")
+	promAlias := gen.ImportAlias(importMap, prometheusImport)
+	if !strings.Contains(src, timeAlias+".Now()") {
+		t.Errorf("generated source doesn't call %s.Now():\n%s", timeAlias, src)
+	}
+	if !strings.Contains(src, promAlias+".CounterVec") {
+		t.Errorf("generated source doesn't reference %s.CounterVec:\n%s", promAlias, src)
+	}
+}
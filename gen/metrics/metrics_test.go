@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func TestLastErrorIndex(t *testing.T) {
+	tests := []struct {
+		name string
+		m    types.Method
+		want int
+	}{
+		{
+			name: "no returns",
+			m:    types.Method{},
+			want: -1,
+		},
+		{
+			name: "trailing error",
+			m: types.Method{Returns: []types.Param{
+				types.NamedParam{Typ: "string"},
+				types.NamedParam{Typ: "error"},
+			}},
+			want: 1,
+		},
+		{
+			name: "no error return",
+			m:    types.Method{Returns: []types.Param{types.NamedParam{Typ: "string"}}},
+			want: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastErrorIndex(tt.m); got != tt.want {
+				t.Errorf("lastErrorIndex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package gen
+
+import "github.com/hfaulds/tracer/parse/types"
+
+// Plugin is a wrapper generator that can be selected by name from a
+// tracer.yaml wrappers list. tracing.Gen and timing.Gen are both
+// Plugins; out-of-tree generators can register their own with Register.
+type Plugin interface {
+	Name() string
+	ShouldSkip(types.Interface) bool
+	Gen(b Builder, iface types.Interface, importMap map[string]string, arg string) string
+}
+
+// ImportsProvider is implemented by a Plugin whose generated struct
+// fields or method bodies reference packages that the wrapped interface
+// doesn't already import (time, a metrics client, ...). The caller folds
+// these into the import map before calling any Plugin's Gen, the same
+// way it already does for the wrapped interface's own imports.
+type ImportsProvider interface {
+	Imports() []string
+}
+
+var plugins = map[string]Plugin{}
+
+// Register adds a Plugin to the registry under its Name, so config can
+// reference it by that name in a wrappers: [...] list. Built-in plugins
+// call this from an init() in their own package.
+func Register(p Plugin) {
+	plugins[p.Name()] = p
+}
+
+// Lookup returns the Plugin registered under name, if any.
+func Lookup(name string) (Plugin, bool) {
+	p, ok := plugins[name]
+	return p, ok
+}
@@ -0,0 +1,82 @@
+// Package retry generates a wrapper that retries methods whose only
+// return is error against a configurable backoff policy.
+package retry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func init() {
+	gen.Register(plugin{})
+}
+
+// backoffImport is the import path for the backoff.BackOff and
+// backoff.Retry the generated struct and method bodies use.
+const backoffImport = "github.com/cenkalti/backoff/v4"
+
+// plugin adapts Gen to gen.Plugin so retry can be selected by name from
+// tracer.yaml.
+type plugin struct{}
+
+func (plugin) Name() string { return "retry" }
+
+func (plugin) ShouldSkip(types.Interface) bool { return false }
+
+func (plugin) Imports() []string { return []string{backoffImport} }
+
+func (plugin) Gen(b gen.Builder, iface types.Interface, importMap map[string]string, arg string) string {
+	return Gen(b, iface, importMap, arg)
+}
+
+// Gen writes a retry wrapper around iface to b: every method whose only
+// return is error is retried via backoff.Retry against the struct's
+// policy field until it succeeds or the policy gives up. If the method
+// takes a context.Context, the policy is wrapped with backoff.WithContext
+// so a canceled context stops the retry loop too. Methods with
+// additional return values pass straight through, since there's nowhere
+// to stash their results across retry attempts without a typed local
+// the generator can't yet name. It returns the generated struct's name.
+func Gen(b gen.Builder, iface types.Interface, importMap map[string]string, policyVar string) string {
+	name := iface.Name + "Retry"
+	strct := types.Struct{
+		Name: name,
+		Attrs: []types.Attr{
+			{Name: "next", Type: types.NamedParam{Typ: iface.Name + gen.WrappedTypeArgs(iface)}},
+			{Name: "policy", Type: types.NamedParam{Pkg: backoffImport, Typ: "BackOff"}},
+		},
+		TypeParams: iface.TypeParams,
+	}
+	b.WriteStruct(strct)
+	backoffPkg := gen.ImportAlias(importMap, backoffImport)
+	for _, m := range iface.Methods {
+		method := m
+		b.WriteMethod(&strct, method, func(b gen.Builder) {
+			if !gen.IsErrorOnly(method) {
+				writeCall(b, "t.next", method)
+				return
+			}
+			args := strings.Join(gen.ParamRefs(method.Params), ", ")
+			policy := "t.policy"
+			if ctx := gen.ContextParamIndex(method); ctx >= 0 {
+				policy = fmt.Sprintf("%s.WithContext(t.policy, p%d)", backoffPkg, ctx)
+			}
+			b.WriteLine("return %s.Retry(func() error {", backoffPkg)
+			b.WriteLine("return t.next.%s(%s)", method.Name, args)
+			b.WriteLine("}, %s)", policy)
+		})
+	}
+	return name
+}
+
+func writeCall(b gen.Builder, receiver string, m types.Method) {
+	call := fmt.Sprintf("%s.%s(%s)", receiver, m.Name, strings.Join(gen.ParamRefs(m.Params), ", "))
+	if len(m.Returns) > 0 {
+		b.WriteLine("return %s", call)
+	} else {
+		b.WriteLine("%s", call)
+	}
+}
@@ -0,0 +1,38 @@
+package retry
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func TestGenWrapsPolicyWithContext(t *testing.T) {
+	iface := types.Interface{
+		Name: "Client",
+		Methods: []types.Method{
+			{
+				Name:    "Do",
+				Params:  []types.Param{types.NamedParam{Pkg: "context", Typ: "Context"}},
+				Returns: []types.Param{types.NamedParam{Typ: "error"}},
+			},
+		},
+	}
+	importMap := gen.BuildImportMap(&types.Package{PkgPath: "example.com/client"}, backoffImport)
+
+	b := &gen.Buffer{ImportMap: importMap}
+	Gen(b, iface, importMap, "policy")
+
+	var out bytes.Buffer
+	if _, err := b.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v: %s", err, out.String())
+	}
+	src := out.String()
+
+	alias := gen.ImportAlias(importMap, backoffImport)
+	if !strings.Contains(src, alias+".WithContext(t.policy, p0)") {
+		t.Errorf("generated source doesn't wrap the policy with the method's context:\n%s", src)
+	}
+}
@@ -0,0 +1,77 @@
+// Package timing generates a wrapper that accumulates each method's
+// wall-clock duration onto a time.Duration field of the generated wrapper
+// struct.
+package timing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func init() {
+	gen.Register(plugin{})
+}
+
+// plugin adapts Gen to gen.Plugin so timing can
+// be selected by name from tracer.yaml.
+type plugin struct{}
+
+func (plugin) Name() string { return "timing" }
+
+func (plugin) ShouldSkip(types.Interface) bool { return false }
+
+func (plugin) Imports() []string { return []string{"time"} }
+
+func (plugin) Gen(b gen.Builder, iface types.Interface, importMap map[string]string, arg string) string {
+	return Gen(b, iface, importMap, arg)
+}
+
+// StructHasTimingAttr reports whether strct has a field named attr. Gen
+// does not consult it: it declares its own attr field on the generated
+// struct.
+func StructHasTimingAttr(strct types.Struct, attr string) bool {
+	for _, a := range strct.Attrs {
+		if a.Name == attr {
+			return true
+		}
+	}
+	return false
+}
+
+// Gen writes a timing wrapper around iface to b: every method records
+// its wall-clock duration onto the timingAttr field before returning.
+// It returns the generated struct's name.
+func Gen(b gen.Builder, iface types.Interface, importMap map[string]string, timingAttr string) string {
+	name := iface.Name + "Timing"
+	strct := types.Struct{
+		Name: name,
+		Attrs: []types.Attr{
+			{Name: "next", Type: types.NamedParam{Typ: iface.Name + gen.WrappedTypeArgs(iface)}},
+			{Name: timingAttr, Type: types.NamedParam{Pkg: "time", Typ: "Duration"}},
+		},
+		TypeParams: iface.TypeParams,
+	}
+	b.WriteStruct(strct)
+	timePkg := gen.ImportAlias(importMap, "time")
+	for _, m := range iface.Methods {
+		method := m
+		b.WriteMethod(&strct, method, func(b gen.Builder) {
+			b.WriteLine("start := %s.Now()", timePkg)
+			b.WriteLine("defer func() { t.%s += %s.Since(start) }()", timingAttr, timePkg)
+			writeCall(b, "t.next", method)
+		})
+	}
+	return name
+}
+
+func writeCall(b gen.Builder, receiver string, m types.Method) {
+	call := fmt.Sprintf("%s.%s(%s)", receiver, m.Name, strings.Join(gen.ParamRefs(m.Params), ", "))
+	if len(m.Returns) > 0 {
+		b.WriteLine("return %s", call)
+	} else {
+		b.WriteLine("%s", call)
+	}
+}
@@ -0,0 +1,43 @@
+package timing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func TestGenImportsTimePackage(t *testing.T) {
+	iface := types.Interface{
+		Name: "Client",
+		Methods: []types.Method{
+			{Name: "Do", Returns: []types.Param{types.NamedParam{Typ: "error"}}},
+		},
+	}
+	importMap := gen.BuildImportMap(&types.Package{PkgPath: "example.com/client"}, "time")
+
+	b := &gen.Buffer{ImportMap: importMap}
+	Gen(b, iface, importMap, "elapsed")
+
+	var out bytes.Buffer
+	if _, err := b.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v: %s", err, out.String())
+	}
+	src := out.String()
+
+	alias := gen.ImportAlias(importMap, "time")
+	if alias == "time" {
+		t.Fatalf("expected \"time\" to be aliased in importMap, got bare package name")
+	}
+	if !strings.Contains(src, alias+".Now()") {
+		t.Errorf("generated source doesn't call %s.Now():\n%s", alias, src)
+	}
+	if !strings.Contains(src, alias+".Since(start)") {
+		t.Errorf("generated source doesn't call %s.Since(start):\n%s", alias, src)
+	}
+	if !strings.Contains(src, alias+".Duration") {
+		t.Errorf("generated source doesn't reference %s.Duration:\n%s", alias, src)
+	}
+}
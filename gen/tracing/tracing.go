@@ -0,0 +1,73 @@
+// Package tracing generates a wrapper that starts a span for every
+// context-taking method of an interface before calling through to the
+// wrapped implementation.
+package tracing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hfaulds/tracer/gen"
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func init() {
+	gen.Register(plugin{})
+}
+
+// plugin adapts Gen and ShouldSkipInterface to gen.Plugin so tracing can
+// be selected by name from tracer.yaml.
+type plugin struct{}
+
+func (plugin) Name() string { return "tracing" }
+
+func (plugin) ShouldSkip(iface types.Interface) bool { return ShouldSkipInterface(iface) }
+
+func (plugin) Gen(b gen.Builder, iface types.Interface, importMap map[string]string, arg string) string {
+	return Gen(b, iface, importMap, arg)
+}
+
+// ShouldSkipInterface reports whether none of iface's methods take a
+// context.Context, in which case there is nothing to start a span from.
+func ShouldSkipInterface(iface types.Interface) bool {
+	for _, m := range iface.Methods {
+		if gen.ContextParamIndex(m) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Gen writes a tracing wrapper around iface to b: every method that
+// takes a context.Context starts a span named "<tracingPkg>.<Method>",
+// replaces the context with the span's, and finishes the span once the
+// call returns. It returns the generated struct's name.
+func Gen(b gen.Builder, iface types.Interface, importMap map[string]string, tracingPkg string) string {
+	name := iface.Name + "Tracing"
+	strct := types.Struct{
+		Name:       name,
+		Attrs:      []types.Attr{{Name: "next", Type: types.NamedParam{Typ: iface.Name + gen.WrappedTypeArgs(iface)}}},
+		TypeParams: iface.TypeParams,
+	}
+	b.WriteStruct(strct)
+	for _, m := range iface.Methods {
+		method := m
+		b.WriteMethod(&strct, method, func(b gen.Builder) {
+			if ctx := gen.ContextParamIndex(method); ctx >= 0 {
+				b.WriteLine("span, p%d := %s.StartSpanFromContext(p%d, %q)", ctx, tracingPkg, ctx, tracingPkg+"."+method.Name)
+				b.WriteLine("defer span.Finish()")
+			}
+			writeCall(b, "t.next", method)
+		})
+	}
+	return name
+}
+
+func writeCall(b gen.Builder, receiver string, m types.Method) {
+	call := fmt.Sprintf("%s.%s(%s)", receiver, m.Name, strings.Join(gen.ParamRefs(m.Params), ", "))
+	if len(m.Returns) > 0 {
+		b.WriteLine("return %s", call)
+	} else {
+		b.WriteLine("%s", call)
+	}
+}
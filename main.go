@@ -7,114 +7,227 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/hfaulds/tracer/api"
+	"github.com/hfaulds/tracer/config"
 	"github.com/hfaulds/tracer/gen"
 	"github.com/hfaulds/tracer/gen/constructor"
-	"github.com/hfaulds/tracer/gen/timing"
-	"github.com/hfaulds/tracer/gen/tracing"
+	_ "github.com/hfaulds/tracer/gen/circuit"
+	_ "github.com/hfaulds/tracer/gen/metrics"
+	_ "github.com/hfaulds/tracer/gen/retry"
+	_ "github.com/hfaulds/tracer/gen/timing"
+	_ "github.com/hfaulds/tracer/gen/tracing"
 	"github.com/hfaulds/tracer/parse"
 	"github.com/hfaulds/tracer/parse/types"
 )
 
-//go:generate code-gen ./ -interface=Client -struct=client
-//go:generate code-gen ./ -interface=Client -tracing=pkg
-//go:generate code-gen ./ -interface=Client -struct=client -tracing=pkg
-//go:generate code-gen ./ -interface=Client -struct=client -tracing=pkg -o client_gen.go
-//go:generate code-gen ./ -interface=Client -struct=client -timing
-
-type flags struct {
-	interfaceName string
-	structName    string
-	tracingPkg    string
-	timingAttr    string
-	output        string
-}
+//go:generate code-gen generate
 
 func main() {
-	f := new(flags)
-	flag.StringVar(&f.interfaceName, "interface", "", "Interface to generate wrappers for")
-	flag.StringVar(&f.structName, "struct", "", "Toggles constructor generation and the struct to return. When used in combination with other flags it will construct the generated wrappers.")
-	flag.StringVar(&f.tracingPkg, "tracing", "", "Toggles tracing wrapper generation")
-	flag.StringVar(&f.timingAttr, "timing", "", "Toggles timing wrapper generation")
-
-	flag.StringVar(&f.output, "o", "", "Output file; defaults to stdout.")
-
-	flag.Parse()
-	if flag.NArg() < 1 {
+	if len(os.Args) < 2 {
 		usage()
-		log.Fatalf("Expected at least one arguments, received %d", flag.NArg())
+		log.Fatal("Expected a subcommand")
 	}
-	if len(f.interfaceName) < 1 {
-		log.Fatal("required flag -interface missing")
+	switch os.Args[1] {
+	case "generate":
+		runGenerate(os.Args[2:])
+	case "api":
+		runAPI(os.Args[2:])
+	default:
+		usage()
+		log.Fatalf("Unknown subcommand %q", os.Args[1])
 	}
-	if len(f.structName) < 1 {
-		log.Fatal("required flag -struct missing")
+}
+
+func usage() {
+	io.WriteString(os.Stderr, usageText)
+	flag.PrintDefaults()
+}
+
+const usageText = `
+tracer generate [tracer.yaml]
+tracer api [-c old.txt] [-allow_new] [-except=Interface1,Interface2] [tracer.yaml]
+`
+
+func runGenerate(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	fs.Parse(args)
+
+	configPath := "tracer.yaml"
+	if fs.NArg() > 0 {
+		configPath = fs.Arg(0)
 	}
 
-	pkg, err := parse.ParseDir(flag.Arg(0))
+	cfg, err := config.Load(configPath)
 	if err != nil {
-		log.Fatalf("Failed to parse %s", err)
+		log.Fatalf("Failed to load %s: %v", configPath, err)
 	}
 
-	importMap := gen.BuildImportMap(pkg)
+	for _, entry := range cfg.Interfaces {
+		if err := generate(entry); err != nil {
+			log.Fatalf("Generating %s.%s: %v", entry.Package, entry.Interface, err)
+		}
+	}
+}
 
-	var b gen.Buffer
-	fmt.Fprint(&b, "// Code generated by tracer v0.0.1. DO NOT EDIT.\n\n")
-	fmt.Fprintf(&b, "package %s\n\n", pkg.Name)
-	gen.GenerateImports(&b, importMap)
+func generate(entry config.InterfaceConfig) error {
+	pkg, err := parse.ParseDir(entry.Package)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", entry.Package, err)
+	}
 
-	iface, ok := findInterface(pkg, f.interfaceName)
+	iface, ok := findInterface(pkg, entry.Interface)
 	if !ok {
-		log.Fatalf("Could not find interface: %s", f.interfaceName)
+		return fmt.Errorf("could not find interface %s", entry.Interface)
 	}
-	strct, ok := findStruct(pkg, f.structName)
+	strct, ok := findStruct(pkg, entry.Struct)
 	if !ok {
-		log.Fatalf("Could not find struct: %s", f.structName)
+		return fmt.Errorf("could not find struct %s", entry.Struct)
+	}
+	if len(entry.Instantiate) > 0 {
+		iface = gen.Instantiate(iface, entry.Instantiate)
 	}
 
-	var wrappers []string
-	if len(f.tracingPkg) > 0 {
-		if tracing.ShouldSkipInterface(iface) {
-			log.Fatal("Could not find any methods taking context")
+	var wrapperPlugins []gen.Plugin
+	var extraImports []string
+	for _, name := range entry.Wrappers {
+		plugin, ok := gen.Lookup(name)
+		if !ok {
+			return fmt.Errorf("unknown wrapper plugin %q", name)
 		}
-		tracingWrapper := tracing.Gen(&b, iface, importMap, f.tracingPkg)
-		wrappers = append(wrappers, tracingWrapper)
-	}
-	if len(f.timingAttr) > 0 {
-		if !timing.StructHasTimingAttr(strct, f.timingAttr) {
-			log.Fatal("Struct does not have specific timing attribute")
+		if plugin.ShouldSkip(iface) {
+			return fmt.Errorf("wrapper %q cannot be generated for %s", name, iface.Name)
+		}
+		wrapperPlugins = append(wrapperPlugins, plugin)
+		if ip, ok := plugin.(gen.ImportsProvider); ok {
+			extraImports = append(extraImports, ip.Imports()...)
 		}
-		timingWrapper := timing.Gen(&b, iface, importMap, f.timingAttr)
-		wrappers = append(wrappers, timingWrapper)
+	}
+
+	importMap := gen.BuildImportMap(pkg, extraImports...)
+
+	var b gen.Buffer
+	b.WriteLine("// Code generated by tracer v0.0.1. DO NOT EDIT.")
+	b.WriteLine("package %s", pkg.Name)
+	gen.GenerateImports(&b, importMap)
+
+	var wrappers []string
+	for i, name := range entry.Wrappers {
+		wrappers = append(wrappers, wrapperPlugins[i].Gen(&b, iface, importMap, entry.Args[name]))
 	}
 	constructor.Gen(&b, importMap, iface, strct, wrappers)
 
 	dst := os.Stdout
-	if len(f.output) > 0 {
-		if err := os.MkdirAll(filepath.Dir(f.output), os.ModePerm); err != nil {
-			log.Fatalf("Unable to create directory: %v", err)
+	if len(entry.Output) > 0 {
+		if err := os.MkdirAll(filepath.Dir(entry.Output), os.ModePerm); err != nil {
+			return fmt.Errorf("unable to create directory: %w", err)
 		}
-		f, err := os.Create(f.output)
+		f, err := os.Create(entry.Output)
 		if err != nil {
-			log.Fatalf("Failed opening destination file: %v", err)
+			return fmt.Errorf("failed opening destination file: %w", err)
 		}
 		defer f.Close()
 		dst = f
 	}
 
 	if _, err := b.WriteTo(dst); err != nil {
-		log.Fatalf("Failed writing to destination: %v", err)
+		return fmt.Errorf("failed writing to destination: %w", err)
 	}
+	return nil
 }
 
-func usage() {
-	io.WriteString(os.Stderr, usageText)
-	flag.PrintDefaults()
+func runAPI(args []string) {
+	fs := flag.NewFlagSet("api", flag.ExitOnError)
+	checkPath := fs.String("c", "", "Manifest to diff the current API against; when set, exits non-zero on breaking changes")
+	allowNew := fs.Bool("allow_new", false, "Permit additions to the API without failing")
+	except := fs.String("except", "", "Comma-separated interface names to exclude from the check")
+	fs.Parse(args)
+
+	configPath := "tracer.yaml"
+	if fs.NArg() > 0 {
+		configPath = fs.Arg(0)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load %s: %v", configPath, err)
+	}
+
+	exceptions := map[string]bool{}
+	for _, name := range strings.Split(*except, ",") {
+		if name != "" {
+			exceptions[name] = true
+		}
+	}
+
+	var entries []api.Entry
+	var exceptPrefixes []string
+	for _, e := range cfg.Interfaces {
+		pkg, err := parse.ParseDir(e.Package)
+		if err != nil {
+			log.Fatalf("Parsing %s: %v", e.Package, err)
+		}
+		iface, ok := findInterface(pkg, e.Interface)
+		if !ok {
+			log.Fatalf("Could not find interface %s", e.Interface)
+		}
+		if exceptions[e.Interface] {
+			exceptPrefixes = append(exceptPrefixes, pkg.PkgPath+"."+e.Interface+".")
+			continue
+		}
+		entries = append(entries, api.Entry{Package: pkg, Interface: iface})
+	}
+
+	manifest := api.Manifest(entries)
+
+	if *checkPath == "" {
+		for _, line := range manifest {
+			fmt.Println(line)
+		}
+		return
+	}
+
+	old, err := api.ReadManifest(*checkPath)
+	if err != nil {
+		log.Fatalf("Reading %s: %v", *checkPath, err)
+	}
+	old = filterExcepted(old, exceptPrefixes)
+
+	removed, added := api.Diff(old, manifest)
+	for _, l := range removed {
+		fmt.Printf("- %s\n", l)
+	}
+	for _, l := range added {
+		fmt.Printf("+ %s\n", l)
+	}
+	if len(removed) > 0 || (len(added) > 0 && !*allowNew) {
+		os.Exit(1)
+	}
 }
 
-const usageText = `
-grep [-trace=Interface] [-o=dest.go] [file]
-`
+// filterExcepted drops every line of manifest whose "pkg.Interface."
+// prefix matches one of exceptPrefixes, so an -except'd interface is
+// ignored on both sides of the diff rather than just the new manifest.
+func filterExcepted(manifest []string, exceptPrefixes []string) []string {
+	if len(exceptPrefixes) == 0 {
+		return manifest
+	}
+	var out []string
+	for _, line := range manifest {
+		excepted := false
+		for _, prefix := range exceptPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				excepted = true
+				break
+			}
+		}
+		if !excepted {
+			out = append(out, line)
+		}
+	}
+	return out
+}
 
 func findInterface(pkg *types.Package, name string) (types.Interface, bool) {
 	for _, iface := range pkg.Interfaces {
@@ -0,0 +1,194 @@
+// Package parse builds a parse/types.Package from the real Go type
+// information for a directory, the same way cmd/api computes exported
+// APIs: load with go/packages, then walk go/types.Info rather than the
+// raw AST. This gives correct handling of variadic parameters, type
+// aliases, method sets promoted through embedding, and identifiers that
+// only resolve once imports are loaded, none of which a hand-rolled AST
+// walk gets right.
+package parse
+
+import (
+	"fmt"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+
+	ptypes "github.com/hfaulds/tracer/parse/types"
+)
+
+const loadMode = packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax | packages.NeedImports
+
+// ParseDir loads the package in dir and returns every interface and
+// struct it declares.
+func ParseDir(dir string) (*ptypes.Package, error) {
+	cfg := &packages.Config{Mode: loadMode, Dir: dir}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, fmt.Errorf("loading package: %w", err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no package found in %s", dir)
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return nil, fmt.Errorf("parsing %s: %v", dir, pkg.Errors[0])
+	}
+
+	out := &ptypes.Package{
+		Name:    pkg.Types.Name(),
+		PkgPath: pkg.Types.Path(),
+	}
+
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok {
+			continue
+		}
+		switch underlying := named.Underlying().(type) {
+		case *types.Interface:
+			iface := convertInterface(out.PkgPath, obj.Name(), underlying)
+			iface.TypeParams = convertTypeParams(named.TypeParams())
+			out.Interfaces = append(out.Interfaces, iface)
+		case *types.Struct:
+			strct := convertStruct(out.PkgPath, obj.Name(), underlying)
+			strct.TypeParams = convertTypeParams(named.TypeParams())
+			out.Structs = append(out.Structs, strct)
+		}
+	}
+	return out, nil
+}
+
+func convertInterface(pkgPath, name string, iface *types.Interface) ptypes.Interface {
+	complete := iface
+	if !iface.IsImplicit() {
+		complete = iface.Complete()
+	}
+	methods := make([]ptypes.Method, 0, complete.NumMethods())
+	for i := 0; i < complete.NumMethods(); i++ {
+		methods = append(methods, convertMethod(pkgPath, complete.Method(i)))
+	}
+	return ptypes.Interface{Name: name, Methods: methods}
+}
+
+func convertStruct(pkgPath, name string, strct *types.Struct) ptypes.Struct {
+	attrs := make([]ptypes.Attr, 0, strct.NumFields())
+	for i := 0; i < strct.NumFields(); i++ {
+		field := strct.Field(i)
+		attrs = append(attrs, ptypes.Attr{Name: field.Name(), Type: convertType(pkgPath, field.Type())})
+	}
+	return ptypes.Struct{Name: name, Attrs: attrs}
+}
+
+func convertMethod(pkgPath string, fn *types.Func) ptypes.Method {
+	sig := fn.Type().(*types.Signature)
+	return ptypes.Method{
+		Name:       fn.Name(),
+		Params:     convertParams(pkgPath, sig),
+		Returns:    convertTuple(pkgPath, sig.Results()),
+		TypeParams: convertTypeParams(sig.TypeParams()),
+	}
+}
+
+// convertParams converts sig's parameters, representing the trailing
+// `...T` of a variadic signature as a VariadicParam rather than the
+// SliceParam its underlying []T would otherwise become.
+func convertParams(pkgPath string, sig *types.Signature) []ptypes.Param {
+	tuple := sig.Params()
+	params := make([]ptypes.Param, 0, tuple.Len())
+	for i := 0; i < tuple.Len(); i++ {
+		t := tuple.At(i).Type()
+		if sig.Variadic() && i == tuple.Len()-1 {
+			if slice, ok := t.(*types.Slice); ok {
+				params = append(params, ptypes.VariadicParam{Typ: convertType(pkgPath, slice.Elem())})
+				continue
+			}
+		}
+		params = append(params, convertType(pkgPath, t))
+	}
+	return params
+}
+
+// convertTypeParams converts a *types.TypeParamList. tparams is nil for
+// every non-generic interface, struct and method, which is the common case.
+func convertTypeParams(tparams *types.TypeParamList) []ptypes.TypeParam {
+	if tparams == nil {
+		return nil
+	}
+	out := make([]ptypes.TypeParam, 0, tparams.Len())
+	for i := 0; i < tparams.Len(); i++ {
+		tp := tparams.At(i)
+		own := tp.Obj().Pkg()
+		constraint := types.TypeString(tp.Constraint(), func(p *types.Package) string {
+			if p == own {
+				return ""
+			}
+			return p.Name()
+		})
+		out = append(out, ptypes.TypeParam{Name: tp.Obj().Name(), Constraint: constraint})
+	}
+	return out
+}
+
+func convertTuple(pkgPath string, tuple *types.Tuple) []ptypes.Param {
+	params := make([]ptypes.Param, 0, tuple.Len())
+	for i := 0; i < tuple.Len(); i++ {
+		params = append(params, convertType(pkgPath, tuple.At(i).Type()))
+	}
+	return params
+}
+
+func convertType(pkgPath string, t types.Type) ptypes.Param {
+	switch tt := t.(type) {
+	case *types.Basic:
+		return ptypes.BasicParam{Typ: tt.Name()}
+	case *types.Named:
+		obj := tt.Obj()
+		if obj.Pkg() == nil || obj.Pkg().Path() == pkgPath {
+			return ptypes.NamedParam{Typ: obj.Name()}
+		}
+		return ptypes.NamedParam{Pkg: obj.Pkg().Path(), Typ: obj.Name()}
+	case *types.Array:
+		return ptypes.ArrayParam{Length: tt.Len(), Typ: convertType(pkgPath, tt.Elem())}
+	case *types.Slice:
+		return ptypes.SliceParam{Typ: convertType(pkgPath, tt.Elem())}
+	case *types.Pointer:
+		return ptypes.PointerParam{Typ: convertType(pkgPath, tt.Elem())}
+	case *types.Map:
+		return ptypes.MapParam{Key: convertType(pkgPath, tt.Key()), Elem: convertType(pkgPath, tt.Elem())}
+	case *types.Interface:
+		return ptypes.InterfaceParam{Methods: interfaceMethods(pkgPath, tt)}
+	case *types.TypeParam:
+		return ptypes.NamedParam{Typ: tt.Obj().Name()}
+	case *types.Chan:
+		return ptypes.ChanParam{Dir: convertChanDir(tt.Dir()), Typ: convertType(pkgPath, tt.Elem())}
+	case *types.Signature:
+		return ptypes.FuncParam{Params: convertParams(pkgPath, tt), Returns: convertTuple(pkgPath, tt.Results())}
+	default:
+		return ptypes.BasicParam{Typ: t.String()}
+	}
+}
+
+func convertChanDir(dir types.ChanDir) ptypes.ChanDir {
+	switch dir {
+	case types.SendOnly:
+		return ptypes.SendOnly
+	case types.RecvOnly:
+		return ptypes.RecvOnly
+	default:
+		return ptypes.SendRecv
+	}
+}
+
+func interfaceMethods(pkgPath string, iface *types.Interface) []ptypes.Method {
+	complete := iface.Complete()
+	methods := make([]ptypes.Method, 0, complete.NumMethods())
+	for i := 0; i < complete.NumMethods(); i++ {
+		methods = append(methods, convertMethod(pkgPath, complete.Method(i)))
+	}
+	return methods
+}
@@ -0,0 +1,97 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/hfaulds/tracer/parse/types"
+)
+
+func TestParseDir(t *testing.T) {
+	pkg, err := ParseDir("../testdata")
+	if err != nil {
+		t.Fatalf("ParseDir() error = %v", err)
+	}
+
+	if pkg.Name != "testdata" {
+		t.Errorf("pkg.Name = %q, want %q", pkg.Name, "testdata")
+	}
+	if pkg.PkgPath != "github.com/hfaulds/tracer/testdata" {
+		t.Errorf("pkg.PkgPath = %q, want %q", pkg.PkgPath, "github.com/hfaulds/tracer/testdata")
+	}
+
+	noMethods := findIface(t, pkg, "noMethods")
+	if len(noMethods.Methods) != 0 {
+		t.Errorf("noMethods has %d methods, want 0", len(noMethods.Methods))
+	}
+
+	noMethodsWithContext := findIface(t, pkg, "noMethodsWithContext")
+	findMethod(t, noMethodsWithContext, "withoutContext")
+
+	methodsWithContext := findIface(t, pkg, "methodsWithContext")
+
+	withContext := findMethod(t, methodsWithContext, "withContext")
+	wantParam(t, "withContext", withContext.Params, 0, types.NamedParam{Pkg: "context", Typ: "Context"})
+
+	withReturnType := findMethod(t, methodsWithContext, "withReturnType")
+	wantParam(t, "withReturnType", withReturnType.Returns, 0, types.BasicParam{Typ: "string"})
+
+	arrayType := findMethod(t, methodsWithContext, "arrayType")
+	wantParam(t, "arrayType", arrayType.Params, 1, types.ArrayParam{Length: 10, Typ: types.BasicParam{Typ: "int"}})
+
+	sliceType := findMethod(t, methodsWithContext, "sliceType")
+	wantParam(t, "sliceType", sliceType.Params, 1, types.SliceParam{Typ: types.BasicParam{Typ: "int"}})
+
+	pointerType := findMethod(t, methodsWithContext, "pointerType")
+	wantParam(t, "pointerType", pointerType.Params, 1, types.PointerParam{Typ: types.BasicParam{Typ: "int"}})
+
+	mapType := findMethod(t, methodsWithContext, "mapType")
+	wantParam(t, "mapType", mapType.Params, 1, types.MapParam{Key: types.BasicParam{Typ: "int"}, Elem: types.BasicParam{Typ: "string"}})
+
+	interfaceType := findMethod(t, methodsWithContext, "interfaceType")
+	ip, ok := interfaceType.Params[1].(types.InterfaceParam)
+	if !ok {
+		t.Fatalf("interfaceType param 1 = %#v, want InterfaceParam", interfaceType.Params[1])
+	}
+	findMethod(t, types.Interface{Methods: ip.Methods}, "Foo")
+
+	interfaceTypeWithEmbed := findMethod(t, methodsWithContext, "interfaceTypeWithEmbed")
+	ip, ok = interfaceTypeWithEmbed.Params[1].(types.InterfaceParam)
+	if !ok {
+		t.Fatalf("interfaceTypeWithEmbed param 1 = %#v, want InterfaceParam", interfaceTypeWithEmbed.Params[1])
+	}
+	embedIface := types.Interface{Methods: ip.Methods}
+	findMethod(t, embedIface, "Foo")
+	findMethod(t, embedIface, "withoutContext")
+}
+
+func findIface(t *testing.T, pkg *types.Package, name string) types.Interface {
+	t.Helper()
+	for _, iface := range pkg.Interfaces {
+		if iface.Name == name {
+			return iface
+		}
+	}
+	t.Fatalf("interface %s not found", name)
+	return types.Interface{}
+}
+
+func findMethod(t *testing.T, iface types.Interface, name string) types.Method {
+	t.Helper()
+	for _, m := range iface.Methods {
+		if m.Name == name {
+			return m
+		}
+	}
+	t.Fatalf("method %s not found", name)
+	return types.Method{}
+}
+
+func wantParam(t *testing.T, method string, params []types.Param, idx int, want types.Param) {
+	t.Helper()
+	if idx >= len(params) {
+		t.Fatalf("%s has %d params, want at least %d", method, len(params), idx+1)
+	}
+	if got := params[idx]; got != want {
+		t.Errorf("%s param %d = %#v, want %#v", method, idx, got, want)
+	}
+}
@@ -0,0 +1,147 @@
+// Package types models the subset of Go's type system that the generators
+// in gen/ care about: interfaces, structs, methods and their parameter
+// types. Values are produced by parse.ParseDir from go/types information,
+// not from hand-rolled AST walking.
+package types
+
+// Package is a single parsed Go package: its name, import path, and the
+// interfaces/structs the generators can target.
+type Package struct {
+	Name       string
+	PkgPath    string
+	Interfaces []Interface
+	Structs    []Struct
+}
+
+// Interface is an interface type and its full method set, including
+// methods promoted from embedded interfaces (local or imported).
+type Interface struct {
+	Name       string
+	Methods    []Method
+	TypeParams []TypeParam
+	// TypeArgs is set instead of TypeParams when Interface is the
+	// monomorphized result of substituting concrete types for a
+	// generic interface's type parameters (see gen.Instantiate): it's
+	// the source form of each type argument, in TypeParams order, so a
+	// reference to the interface itself can be written out as
+	// "Name[TypeArgs...]".
+	TypeArgs []string
+}
+
+// Struct is a struct type and its fields.
+type Struct struct {
+	Name       string
+	Attrs      []Attr
+	TypeParams []TypeParam
+}
+
+// Attr is a single struct field.
+type Attr struct {
+	Name string
+	Type Param
+}
+
+// Method is a single method signature.
+type Method struct {
+	Name       string
+	Params     []Param
+	Returns    []Param
+	TypeParams []TypeParam
+}
+
+// TypeParam is a single entry in a type parameter list, e.g. the `T any`
+// in `interface[T any]`. Constraint is the constraint's source form
+// (`any`, `constraints.Ordered`, ...) rather than a resolved Param, since
+// constraints are interfaces used only for printing, never substituted.
+type TypeParam struct {
+	Name       string
+	Constraint string
+}
+
+// Param is a type appearing in a method signature or struct field. It is
+// implemented by BasicParam, NamedParam, ArrayParam, SliceParam,
+// PointerParam, MapParam, InterfaceParam, VariadicParam, ChanParam and
+// FuncParam.
+type Param interface {
+	isParam()
+}
+
+// BasicParam is a predeclared type such as string, int or error.
+type BasicParam struct {
+	Typ string
+}
+
+// NamedParam is a defined type, optionally from another package. Pkg is
+// empty for types declared in the package being parsed.
+type NamedParam struct {
+	Pkg string
+	Typ string
+}
+
+// ArrayParam is a fixed-size array type, e.g. [10]int.
+type ArrayParam struct {
+	Length int64
+	Typ    Param
+}
+
+// SliceParam is a slice type, e.g. []int.
+type SliceParam struct {
+	Typ Param
+}
+
+// PointerParam is a pointer type, e.g. *int.
+type PointerParam struct {
+	Typ Param
+}
+
+// MapParam is a map type, e.g. map[int]string.
+type MapParam struct {
+	Key  Param
+	Elem Param
+}
+
+// InterfaceParam is an inline interface type, e.g. interface{ Foo(string) int }.
+type InterfaceParam struct {
+	Methods []Method
+}
+
+// VariadicParam is the trailing `...T` parameter of a variadic method,
+// e.g. the `args ...T` in `Printf(format string, args ...T)`, with T as
+// its Typ. It only ever
+// appears as the last entry of a Method's Params.
+type VariadicParam struct {
+	Typ Param
+}
+
+// ChanDir is a channel type's direction, mirroring go/types.ChanDir.
+type ChanDir int
+
+const (
+	SendRecv ChanDir = iota
+	SendOnly
+	RecvOnly
+)
+
+// ChanParam is a channel type, e.g. chan T, chan<- T or <-chan T.
+type ChanParam struct {
+	Dir ChanDir
+	Typ Param
+}
+
+// FuncParam is a function type used as a value, e.g. a logging callback
+// parameter typed func(string, ...interface{}).
+type FuncParam struct {
+	Params  []Param
+	Returns []Param
+}
+
+func (BasicParam) isParam()     {}
+func (NamedParam) isParam()     {}
+func (ArrayParam) isParam()     {}
+func (SliceParam) isParam()     {}
+func (PointerParam) isParam()   {}
+func (MapParam) isParam()       {}
+func (InterfaceParam) isParam() {}
+func (VariadicParam) isParam()  {}
+func (ChanParam) isParam()      {}
+func (FuncParam) isParam()      {}